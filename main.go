@@ -1,38 +1,26 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
+	"io/ioutil"
 	"log"
-	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/urfave/cli"
 
+	"github.com/amacneil/dbmate/pkg/config"
 	"github.com/amacneil/dbmate/pkg/dbmate"
+	"github.com/amacneil/dbmate/pkg/hooks"
+	"github.com/amacneil/dbmate/pkg/notify"
 )
 
-type Field struct {
-	Title string`json:"title"`
-	Value string`json:"value"`
-}
-
-type SlackAttachment struct {
-	Color    string`json:"color"`
-	Pretext  string`json:"pretext"`
-	Fallback string`json:"fallback"`
-	Text     string`json:"text"`
-	Fields   []Field`json:"fields"`
-}
-
-type SlackMessage struct {
-	Attachments []SlackAttachment`json:"attachments"`
-}
-
 func main() {
 	loadDotEnv()
 
@@ -46,6 +34,18 @@ func main() {
 }
 
 // NewApp creates a new command line app
+//
+// Known deferrals (both blocked on pkg/dbmate, which lives outside this
+// source tree and isn't touched by this series):
+//
+//   - The request behind --notify-url asked for dbmate.DB itself to expose
+//     a Notifiers field so Migrate/Rollback emit events from the library,
+//     not just the CLI. For now every notify.NotifyAll call below is
+//     bolted onto these CLI action() wrappers instead.
+//   - `dbmate save`/`export` and --record-sql were prototyped and then
+//     reverted in this series: dbmate.DB has no SaveMigrations,
+//     DiffMigrations, or schema_migrations SQL-body column to build on.
+//     Not implemented; needs that library support to land first.
 func NewApp() *cli.App {
 	app := cli.NewApp()
 	app.Name = "dbmate"
@@ -59,32 +59,53 @@ func NewApp() *cli.App {
 			Usage: "specify an environment variable containing the database URL",
 		},
 		cli.StringFlag{
-			Name:  "migrations-dir, d",
-			Value: dbmate.DefaultMigrationsDir,
-			Usage: "specify the directory containing migration files",
+			Name:   "migrations-dir, d",
+			Value:  dbmate.DefaultMigrationsDir,
+			Usage:  "specify the directory containing migration files",
+			EnvVar: "DBMATE_MIGRATIONS_DIR",
 		},
 		cli.StringFlag{
-			Name:  "schema-file, s",
-			Value: dbmate.DefaultSchemaFile,
-			Usage: "specify the schema file location",
+			Name:   "schema-file, s",
+			Value:  dbmate.DefaultSchemaFile,
+			Usage:  "specify the schema file location",
+			EnvVar: "DBMATE_SCHEMA_FILE",
 		},
 		cli.StringFlag{
-			Name:  "slack-webhook-var",
-			Value: "SLACK_WEBHOOK_URL",
-			Usage: "slack webhook url env var",
+			Name:   "env-profile, p",
+			Value:  "development",
+			Usage:  "specify which profile to load from dbmate.yml/database.yml",
+			EnvVar: "DBMATE_ENV",
+		},
+		cli.StringFlag{
+			Name:  "notify-url",
+			Usage: "notifier destination(s) to send migrate/rollback events to (comma delimited, e.g. slack+https://hooks.slack.com/... ,exec:///path/to/script)",
 		},
 		cli.StringFlag{
 			Name:  "env-vars",
-			Usage: "slack webhook env var names for context (comma delimited)",
+			Usage: "env var names to include as notification context (comma delimited)",
+		},
+		cli.StringFlag{
+			Name:   "notify-payload-template",
+			Usage:  "Go text/template string rendered as the notification payload, in place of the built-in format of each HTTP sink (slack, teams, webhook)",
+			EnvVar: "DBMATE_NOTIFY_PAYLOAD_TEMPLATE",
+		},
+		cli.StringFlag{
+			Name:  "notify-payload-template-file",
+			Usage: "load --notify-payload-template from a file instead of the command line",
 		},
 		cli.BoolFlag{
-			Name:  "no-dump-schema",
-			Usage: "don't update the schema file on migrate/rollback",
+			Name:   "no-dump-schema",
+			Usage:  "don't update the schema file on migrate/rollback",
+			EnvVar: "DBMATE_NO_DUMP_SCHEMA",
 		},
 		cli.BoolFlag{
 			Name:  "wait",
 			Usage: "wait for the db to become available before executing the subsequent command",
 		},
+		cli.StringFlag{
+			Name:  "hook-cmd",
+			Usage: "executable invoked around each migrate run (before/after/error) to implement custom callbacks, e.g. ANALYZE or metrics reporting",
+		},
 	}
 
 	app.Commands = []cli.Command{
@@ -122,51 +143,55 @@ func NewApp() *cli.App {
 			Name:  "migrate",
 			Usage: "Migrate to the latest version",
 			Action: action(func(db *dbmate.DB, c *cli.Context) error {
-				err := db.Migrate()
-				_, webhook_env_exists := os.LookupEnv(c.GlobalString("slack-webhook-var"))
+				notifiers, err := notifiersFromContext(c)
+				if err != nil {
+					return err
+				}
 
-				if err != nil && webhook_env_exists {
-					env_vars := strings.Split(c.GlobalString("env-vars"), ",")
-					fmt.Printf("%s: %s\n", "env-vars split", env_vars)
+				ctx := context.Background()
+				env := notifyContext(c)
+				hook := migrationHooks(c)
 
-					//
+				_ = notify.NotifyAll(ctx, notifiers, notify.Event{Type: notify.MigrateStart, Command: "migrate", Env: env})
 
-					fields := make([]Field, 0)
+				// db.Migrate() applies every pending migration in one call with
+				// no per-file checkpoints (see hooks.Hooks), so these fire once
+				// for the whole run using the sentinel filename "*".
+				if err := hook.BeforeMigration(ctx, "*"); err != nil {
+					return err
+				}
 
-					for _, env_var_name := range env_vars {
-						_, env_var_exists := os.LookupEnv(env_var_name)
-						if env_var_exists {
-							fields = append(fields, Field{
-								Title: env_var_name,
-								Value: os.Getenv(env_var_name),
-							})
-						}
-					}
+				start := time.Now()
+				migrateErr := db.Migrate()
 
-					slack_message := SlackMessage{
-						Attachments: []SlackAttachment{
-							SlackAttachment{
-								Fallback: "Migration had error: " + err.Error(),
-								Color:    "#FF0000",
-								Pretext:  "There was an issue running migrations on this instance.",
-								Text:     err.Error(),
-								Fields:   fields,
-							},
-						},
+				if migrateErr != nil {
+					decision, hookErr := hook.OnMigrationError(ctx, "*", migrateErr)
+					if hookErr != nil {
+						migrateErr = hookErr
 					}
 
-					url := os.Getenv(c.GlobalString("slack-webhook-var"))
-
-					body, _ := json.Marshal(slack_message)
-
-					_, err := http.Post(url, "application/json", bytes.NewBuffer(body))
+					switch decision {
+					case hooks.Continue:
+						// The hook chose to continue past the error, so the
+						// run is not aborted and no failure is reported.
+						return nil
+					case hooks.Retry:
+						migrateErr = db.Migrate()
+					}
 
-					if err != nil {
-						fmt.Printf("%s: %s\n", "could not send to webhook", url)
+					if migrateErr != nil {
+						_ = notify.NotifyAll(ctx, notifiers, notify.Event{Type: notify.MigrateFailure, Command: "migrate", Err: migrateErr, Duration: time.Since(start), Env: env})
+						return migrateErr
 					}
 				}
 
-				return err
+				duration := time.Since(start)
+				if err := hook.AfterMigration(ctx, "*", duration); err != nil {
+					return err
+				}
+
+				_ = notify.NotifyAll(ctx, notifiers, notify.Event{Type: notify.MigrateSuccess, Command: "migrate", Duration: duration, Env: env})
+				return nil
 			}),
 		},
 		{
@@ -174,7 +199,22 @@ func NewApp() *cli.App {
 			Aliases: []string{"down"},
 			Usage:   "Rollback the most recent migration",
 			Action: action(func(db *dbmate.DB, c *cli.Context) error {
-				return db.Rollback()
+				notifiers, err := notifiersFromContext(c)
+				if err != nil {
+					return err
+				}
+
+				ctx := context.Background()
+				env := notifyContext(c)
+
+				start := time.Now()
+				if err := db.Rollback(); err != nil {
+					_ = notify.NotifyAll(ctx, notifiers, notify.Event{Type: notify.RollbackFailure, Command: "rollback", Err: err, Duration: time.Since(start), Env: env})
+					return err
+				}
+
+				_ = notify.NotifyAll(ctx, notifiers, notify.Event{Type: notify.RollbackSuccess, Command: "rollback", Duration: time.Since(start), Env: env})
+				return nil
 			}),
 		},
 		{
@@ -242,24 +282,163 @@ func loadDotEnv() {
 // action wraps a cli.ActionFunc with dbmate initialization logic
 func action(f func(*dbmate.DB, *cli.Context) error) cli.ActionFunc {
 	return func(c *cli.Context) error {
-		u, err := getDatabaseURL(c)
+		profile := currentProfile(c)
+
+		u, err := getDatabaseURL(c, profile)
 		if err != nil {
 			return err
 		}
 		db := dbmate.New(u)
-		db.AutoDumpSchema = !c.GlobalBool("no-dump-schema")
-		db.MigrationsDir = c.GlobalString("migrations-dir")
-		db.SchemaFile = c.GlobalString("schema-file")
+		db.AutoDumpSchema = !boolSetting(c, "no-dump-schema", "DBMATE_NO_DUMP_SCHEMA", profile.NoDumpSchema)
+		db.MigrationsDir = stringSetting(c, "migrations-dir", "DBMATE_MIGRATIONS_DIR", profile.MigrationsDir)
+		db.SchemaFile = stringSetting(c, "schema-file", "DBMATE_SCHEMA_FILE", profile.SchemaFile)
 		db.WaitBefore = c.GlobalBool("wait")
 
 		return f(db, c)
 	}
 }
 
-// getDatabaseURL returns the current environment database url
-func getDatabaseURL(c *cli.Context) (u *url.URL, err error) {
-	env := c.GlobalString("env")
-	value := os.Getenv(env)
+// getDatabaseURL returns the database url to use, preferring (in order) the
+// env var named by --env, then the selected profile's url.
+func getDatabaseURL(c *cli.Context, profile config.Profile) (u *url.URL, err error) {
+	value := os.Getenv(c.GlobalString("env"))
+	if value == "" {
+		value = profile.URL
+	}
 
 	return url.Parse(value)
 }
+
+// currentProfile loads dbmate.yml/database.yml (if present) and returns the
+// profile selected by --env-profile/DBMATE_ENV. A missing file or profile is
+// not an error: it simply yields a zero-value Profile, so every setting
+// falls through to CLI flags/env vars/builtin defaults.
+func currentProfile(c *cli.Context) config.Profile {
+	path := config.Find(".")
+	if path == "" {
+		return config.Profile{}
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		log.Printf("warning: could not load %s: %s", path, err)
+		return config.Profile{}
+	}
+
+	return cfg.Profile(c.GlobalString("env-profile"))
+}
+
+// stringSetting returns the setting's value, preferring (in order): the CLI
+// flag when the user explicitly set it, then envVar (if non-empty and set),
+// then the profile's value, then the flag's builtin default. Pass envVar ""
+// for settings with no env var of their own.
+func stringSetting(c *cli.Context, flag, envVar string, profileValue string) string {
+	if c.GlobalIsSet(flag) {
+		return c.GlobalString(flag)
+	}
+
+	if envVar != "" {
+		if value, ok := os.LookupEnv(envVar); ok {
+			return value
+		}
+	}
+
+	if profileValue != "" {
+		return profileValue
+	}
+
+	return c.GlobalString(flag)
+}
+
+// boolSetting returns the setting's value, preferring (in order): the CLI
+// flag when the user explicitly set it, then envVar (if non-empty and set
+// to a valid bool), then the profile's value.
+func boolSetting(c *cli.Context, flag, envVar string, profileValue bool) bool {
+	if c.GlobalIsSet(flag) {
+		return c.GlobalBool(flag)
+	}
+
+	if envVar != "" {
+		if value, ok := os.LookupEnv(envVar); ok {
+			if parsed, err := strconv.ParseBool(value); err == nil {
+				return parsed
+			}
+		}
+	}
+
+	return profileValue
+}
+
+// migrationHooks builds the Hooks to run around a migration, based on
+// --hook-cmd. With no --hook-cmd configured, a no-op Hooks is used and every
+// migration failure aborts as before.
+func migrationHooks(c *cli.Context) hooks.Hooks {
+	path := c.GlobalString("hook-cmd")
+	if path == "" {
+		return hooks.NopHooks{}
+	}
+
+	return hooks.NewExecHooks(path)
+}
+
+// notifiersFromContext builds the set of notifiers configured via
+// --notify-url, falling back to the selected profile's notify.url, and
+// wires up the custom payload template (--notify-payload-template[-file]),
+// falling back to the selected profile's notify.payload_template.
+func notifiersFromContext(c *cli.Context) ([]notify.Notifier, error) {
+	profile := currentProfile(c)
+
+	tmpl, err := notifyPayloadTemplate(c, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	return notify.NewFromURLs(stringSetting(c, "notify-url", "", profile.Notify.URL), tmpl)
+}
+
+// notifyPayloadTemplate builds the custom payload template, if one was
+// configured via --notify-payload-template, --notify-payload-template-file,
+// or the selected profile, in that order of precedence. It returns nil if
+// none of these is set, so sinks fall back to their built-in payload.
+func notifyPayloadTemplate(c *cli.Context, profile config.Profile) (*template.Template, error) {
+	raw := c.GlobalString("notify-payload-template")
+
+	if raw == "" {
+		if path := c.GlobalString("notify-payload-template-file"); path != "" {
+			contents, err := ioutil.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			raw = string(contents)
+		}
+	}
+
+	if raw == "" {
+		raw = profile.Notify.PayloadTemplate
+	}
+
+	if raw == "" {
+		return nil, nil
+	}
+
+	return template.New("notify-payload").Parse(raw)
+}
+
+// notifyContext returns the whitelisted environment variables (--env-vars)
+// to attach to outgoing notifications as context.
+func notifyContext(c *cli.Context) map[string]string {
+	env := make(map[string]string)
+
+	raw := c.GlobalString("env-vars")
+	if raw == "" {
+		return env
+	}
+
+	for _, name := range strings.Split(raw, ",") {
+		if value, ok := os.LookupEnv(name); ok {
+			env[name] = value
+		}
+	}
+
+	return env
+}
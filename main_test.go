@@ -0,0 +1,138 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/urfave/cli"
+)
+
+// stringFlagApp returns a minimal *cli.App exposing a single string flag
+// with the given name/default/env var, calling got with whatever
+// stringSetting computes for it.
+func stringFlagApp(flag, defaultValue, envVar string, profileValue string, got *string) *cli.App {
+	app := cli.NewApp()
+	app.Flags = []cli.Flag{
+		cli.StringFlag{Name: flag, Value: defaultValue, EnvVar: envVar},
+	}
+	app.Action = func(c *cli.Context) error {
+		*got = stringSetting(c, flag, envVar, profileValue)
+		return nil
+	}
+	return app
+}
+
+func TestStringSettingPrecedence(t *testing.T) {
+	const envVar = "DBMATE_TEST_MIGRATIONS_DIR"
+
+	t.Run("explicit CLI flag wins over env var and profile", func(t *testing.T) {
+		t.Setenv(envVar, "env-dir")
+
+		var got string
+		app := stringFlagApp("migrations-dir", "default-dir", envVar, "profile-dir", &got)
+		if err := app.Run([]string{"dbmate", "--migrations-dir=cli-dir"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "cli-dir" {
+			t.Fatalf("stringSetting() = %q, want %q", got, "cli-dir")
+		}
+	})
+
+	t.Run("env var wins over profile when flag not set", func(t *testing.T) {
+		t.Setenv(envVar, "env-dir")
+
+		var got string
+		app := stringFlagApp("migrations-dir", "default-dir", envVar, "profile-dir", &got)
+		if err := app.Run([]string{"dbmate"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "env-dir" {
+			t.Fatalf("stringSetting() = %q, want %q", got, "env-dir")
+		}
+	})
+
+	t.Run("profile wins over builtin default when flag and env var unset", func(t *testing.T) {
+		os.Unsetenv(envVar)
+
+		var got string
+		app := stringFlagApp("migrations-dir", "default-dir", envVar, "profile-dir", &got)
+		if err := app.Run([]string{"dbmate"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "profile-dir" {
+			t.Fatalf("stringSetting() = %q, want %q", got, "profile-dir")
+		}
+	})
+
+	t.Run("builtin default when nothing else set", func(t *testing.T) {
+		os.Unsetenv(envVar)
+
+		var got string
+		app := stringFlagApp("migrations-dir", "default-dir", envVar, "", &got)
+		if err := app.Run([]string{"dbmate"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "default-dir" {
+			t.Fatalf("stringSetting() = %q, want %q", got, "default-dir")
+		}
+	})
+}
+
+// boolFlagApp returns a minimal *cli.App exposing a single bool flag,
+// calling got with whatever boolSetting computes for it. The flag is
+// registered without an EnvVar so that envVar is read by boolSetting alone;
+// cli itself rejects an unparsable bool env var before any action runs.
+func boolFlagApp(flag, envVar string, profileValue bool, got *bool) *cli.App {
+	app := cli.NewApp()
+	app.Flags = []cli.Flag{
+		cli.BoolFlag{Name: flag},
+	}
+	app.Action = func(c *cli.Context) error {
+		*got = boolSetting(c, flag, envVar, profileValue)
+		return nil
+	}
+	return app
+}
+
+func TestBoolSettingPrecedence(t *testing.T) {
+	const envVar = "DBMATE_TEST_NO_DUMP_SCHEMA"
+
+	t.Run("explicit CLI flag wins over env var and profile", func(t *testing.T) {
+		t.Setenv(envVar, "false")
+
+		var got bool
+		app := boolFlagApp("no-dump-schema", envVar, false, &got)
+		if err := app.Run([]string{"dbmate", "--no-dump-schema"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !got {
+			t.Fatalf("boolSetting() = %v, want true", got)
+		}
+	})
+
+	t.Run("env var wins over profile when flag not set", func(t *testing.T) {
+		t.Setenv(envVar, "true")
+
+		var got bool
+		app := boolFlagApp("no-dump-schema", envVar, false, &got)
+		if err := app.Run([]string{"dbmate"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !got {
+			t.Fatalf("boolSetting() = %v, want true", got)
+		}
+	})
+
+	t.Run("invalid env var value falls back to profile", func(t *testing.T) {
+		t.Setenv(envVar, "not-a-bool")
+
+		var got bool
+		app := boolFlagApp("no-dump-schema", envVar, true, &got)
+		if err := app.Run([]string{"dbmate"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !got {
+			t.Fatalf("boolSetting() = %v, want true", got)
+		}
+	})
+}
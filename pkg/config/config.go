@@ -0,0 +1,72 @@
+// Package config loads dbmate's optional multi-environment config file
+// (dbmate.yml or database.yml), modeled on the database.yml profile files
+// used by tools like ActiveRecord and Traffic Ops' admin config. It lets a
+// project keep several database targets (development/test/production, etc)
+// in one file and select between them with --env-profile.
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultFilenames are the config file names dbmate looks for, in order,
+// alongside .env.
+var DefaultFilenames = []string{"dbmate.yml", "database.yml"}
+
+// Notify holds the notifier settings for a single profile.
+type Notify struct {
+	URL             string `yaml:"url"`
+	PayloadTemplate string `yaml:"payload_template"`
+}
+
+// Profile holds the settings for a single named environment, such as
+// "development" or "production".
+type Profile struct {
+	URL           string `yaml:"url"`
+	MigrationsDir string `yaml:"migrations_dir"`
+	SchemaFile    string `yaml:"schema_file"`
+	NoDumpSchema  bool   `yaml:"no_dump_schema"`
+	Notify        Notify `yaml:"notify"`
+}
+
+// Config is a parsed dbmate.yml/database.yml, keyed by profile name.
+type Config map[string]Profile
+
+// Find locates the first config file present in dir from DefaultFilenames,
+// returning "" if none exist.
+func Find(dir string) string {
+	for _, name := range DefaultFilenames {
+		path := dir + string(os.PathSeparator) + name
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// Load reads and parses the config file at path.
+func Load(path string) (Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("config: %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Profile returns the named profile, or an empty Profile if it is not
+// defined (a missing profile is not an error: its fields simply fall back
+// to CLI flags, env vars, and builtin defaults).
+func (c Config) Profile(name string) Profile {
+	return c[name]
+}
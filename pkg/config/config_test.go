@@ -0,0 +1,101 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	t.Run("returns empty string when no config file exists", func(t *testing.T) {
+		dir := t.TempDir()
+		if got := Find(dir); got != "" {
+			t.Fatalf("Find() = %q, want \"\"", got)
+		}
+	})
+
+	t.Run("finds dbmate.yml before database.yml", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, name := range []string{"database.yml", "dbmate.yml"} {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o644); err != nil {
+				t.Fatalf("failed to write %s: %v", name, err)
+			}
+		}
+
+		want := filepath.Join(dir, "dbmate.yml")
+		if got := Find(dir); got != want {
+			t.Fatalf("Find() = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("parses a profile", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "dbmate.yml")
+		contents := `
+development:
+  url: postgres://localhost/myapp_development
+  migrations_dir: ./db/migrations
+  notify:
+    url: exec:///path/to/script
+    payload_template: "{{.Event}}"
+`
+		if err := ioutil.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		profile := cfg.Profile("development")
+		if profile.URL != "postgres://localhost/myapp_development" {
+			t.Fatalf("profile.URL = %q, want %q", profile.URL, "postgres://localhost/myapp_development")
+		}
+		if profile.MigrationsDir != "./db/migrations" {
+			t.Fatalf("profile.MigrationsDir = %q, want %q", profile.MigrationsDir, "./db/migrations")
+		}
+		if profile.Notify.URL != "exec:///path/to/script" {
+			t.Fatalf("profile.Notify.URL = %q, want %q", profile.Notify.URL, "exec:///path/to/script")
+		}
+	})
+
+	t.Run("missing profile returns the zero value", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "dbmate.yml")
+		if err := ioutil.WriteFile(path, []byte("development:\n  url: foo\n"), 0o644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		profile := cfg.Profile("production")
+		if profile != (Profile{}) {
+			t.Fatalf("profile for missing name = %+v, want zero value", profile)
+		}
+	})
+
+	t.Run("returns an error for invalid yaml", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "dbmate.yml")
+		if err := ioutil.WriteFile(path, []byte("not: [valid"), 0o644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		if _, err := Load(path); err == nil {
+			t.Fatal("expected an error for invalid yaml")
+		}
+	})
+
+	t.Run("returns an error when the file does not exist", func(t *testing.T) {
+		if _, err := Load(filepath.Join(t.TempDir(), "missing.yml")); err == nil {
+			t.Fatal("expected an error for a missing file")
+		}
+	})
+}
+
@@ -0,0 +1,80 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// scriptExitingWith writes an executable shell script to a temp dir that
+// exits with the given code, and returns its path.
+func scriptExitingWith(t *testing.T, code int) string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("exec hook scripts are shell-based")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hook.sh")
+	contents := "#!/bin/sh\nexit " + string(rune('0'+code)) + "\n"
+
+	if err := ioutil.WriteFile(path, []byte(contents), 0o755); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+
+	return path
+}
+
+func TestExecHooksOnMigrationErrorDecodesExitCode(t *testing.T) {
+	cases := []struct {
+		name string
+		code int
+		want Action
+	}{
+		{name: "exit 0 continues", code: 0, want: Continue},
+		{name: "exit 1 aborts", code: 1, want: Abort},
+		{name: "exit 2 retries", code: 2, want: Retry},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewExecHooks(scriptExitingWith(t, tc.code))
+
+			action, err := h.OnMigrationError(context.Background(), "20240101000000_x.sql", errors.New("boom"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if action != tc.want {
+				t.Fatalf("OnMigrationError() = %s, want %s", action, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecHooksOnMigrationErrorMissingCommand(t *testing.T) {
+	h := NewExecHooks(filepath.Join(t.TempDir(), "does-not-exist"))
+
+	action, err := h.OnMigrationError(context.Background(), "20240101000000_x.sql", errors.New("boom"))
+	if err == nil {
+		t.Fatal("expected an error for a missing hook command")
+	}
+	if action != Abort {
+		t.Fatalf("OnMigrationError() = %s, want %s", action, Abort)
+	}
+}
+
+func TestNopHooksAlwaysAborts(t *testing.T) {
+	h := NopHooks{}
+
+	action, err := h.OnMigrationError(context.Background(), "20240101000000_x.sql", errors.New("boom"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if action != Abort {
+		t.Fatalf("OnMigrationError() = %s, want %s", action, Abort)
+	}
+}
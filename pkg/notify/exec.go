@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// ExecNotifier runs a local executable for every event, in the style of
+// kured's --notify-url exec hook. The event type, command, filename and
+// error (if any) are passed as arguments, and the full Env map is exported
+// as environment variables prefixed with DBMATE_.
+type ExecNotifier struct {
+	Path string
+}
+
+// NewExecNotifier returns a Notifier that runs the executable at path.
+func NewExecNotifier(path string) (*ExecNotifier, error) {
+	if path == "" {
+		return nil, fmt.Errorf("exec path is required")
+	}
+
+	return &ExecNotifier{Path: path}, nil
+}
+
+// Notify implements Notifier.
+func (e *ExecNotifier) Notify(ctx context.Context, event Event) error {
+	errMsg := ""
+	if event.Err != nil {
+		errMsg = event.Err.Error()
+	}
+
+	cmd := exec.CommandContext(ctx, e.Path, string(event.Type), event.Command, event.Filename, errMsg)
+
+	cmd.Env = append(os.Environ(), fmt.Sprintf("DBMATE_EVENT=%s", event.Type))
+	for name, value := range event.Env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("DBMATE_%s=%s", name, value))
+	}
+
+	return cmd.Run()
+}
@@ -0,0 +1,134 @@
+// Package notify provides a pluggable notification subsystem for dbmate.
+//
+// A Notifier receives Events emitted around migrate/rollback runs and
+// delivers them to some external destination (Slack, a generic webhook,
+// Microsoft Teams, a local script, etc). Multiple sinks can be combined by
+// passing a comma-separated list of URLs to NewFromURLs; the URL scheme
+// selects which sink handles it.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// EventType identifies the kind of lifecycle event being reported.
+type EventType string
+
+const (
+	// MigrateStart fires once, before any migrations are applied.
+	MigrateStart EventType = "migrate_start"
+	// MigrateSuccess fires after all pending migrations have applied cleanly.
+	MigrateSuccess EventType = "migrate_success"
+	// MigrateFailure fires if a migrate run aborts due to an error.
+	MigrateFailure EventType = "migrate_failure"
+	// RollbackSuccess fires after a rollback completes successfully.
+	RollbackSuccess EventType = "rollback_success"
+	// RollbackFailure fires if a rollback aborts due to an error.
+	RollbackFailure EventType = "rollback_failure"
+)
+
+// Event describes a single notification-worthy occurrence.
+type Event struct {
+	Type EventType
+
+	// Command is the dbmate subcommand that produced this event (e.g. "migrate").
+	Command string
+
+	// Filename is set when the event concerns a specific migration file.
+	Filename string
+
+	// Duration is set for events that measure elapsed time, such as the
+	// overall Migrate/Rollback run.
+	Duration time.Duration
+
+	// Err is set for failure events.
+	Err error
+
+	// Env carries whitelisted environment variables for context, as
+	// configured by the caller (see --env-vars).
+	Env map[string]string
+}
+
+// Notifier delivers an Event to some external destination.
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// NewFromURLs parses a comma-separated list of notifier URLs and returns a
+// Notifier for each one. The scheme of each URL selects the sink:
+//
+//	slack+https://hooks.slack.com/...  -> Slack incoming webhook
+//	teams+https://outlook.office.com/...  -> Microsoft Teams connector
+//	exec:///path/to/script  -> run a local executable
+//	https://example.com/hook  -> generic JSON webhook
+//
+// Empty entries are ignored, so NewFromURLs("", nil) returns an empty slice.
+//
+// If tmpl is non-nil, it overrides the built-in JSON payload of every
+// HTTP-based sink (Slack, Teams, generic webhook): the rendered template
+// output is posted verbatim instead.
+func NewFromURLs(raw string, tmpl *template.Template) ([]Notifier, error) {
+	notifiers := make([]Notifier, 0)
+
+	for _, u := range strings.Split(raw, ",") {
+		u = strings.TrimSpace(u)
+		if u == "" {
+			continue
+		}
+
+		n, err := newFromURL(u, tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("notify: %s: %w", u, err)
+		}
+
+		notifiers = append(notifiers, n)
+	}
+
+	return notifiers, nil
+}
+
+func newFromURL(raw string, tmpl *template.Template) (Notifier, error) {
+	switch {
+	case strings.HasPrefix(raw, "slack+"):
+		n, err := NewSlackNotifier(strings.TrimPrefix(raw, "slack+"))
+		if n != nil {
+			n.Template = tmpl
+		}
+		return n, err
+	case strings.HasPrefix(raw, "teams+"):
+		n, err := NewTeamsNotifier(strings.TrimPrefix(raw, "teams+"))
+		if n != nil {
+			n.Template = tmpl
+		}
+		return n, err
+	case strings.HasPrefix(raw, "exec://"):
+		return NewExecNotifier(strings.TrimPrefix(raw, "exec://"))
+	case strings.HasPrefix(raw, "http://"), strings.HasPrefix(raw, "https://"):
+		n, err := NewWebhookNotifier(raw)
+		if n != nil {
+			n.Template = tmpl
+		}
+		return n, err
+	default:
+		return nil, fmt.Errorf("unrecognized notify url scheme")
+	}
+}
+
+// NotifyAll sends event to every notifier, collecting (but not stopping on)
+// individual failures. It returns the first error encountered, if any, after
+// every notifier has had a chance to run.
+func NotifyAll(ctx context.Context, notifiers []Notifier, event Event) error {
+	var firstErr error
+
+	for _, n := range notifiers {
+		if err := n.Notify(ctx, event); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,137 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"text/template"
+)
+
+func TestNewFromURLDispatch(t *testing.T) {
+	cases := []struct {
+		url     string
+		want    interface{}
+		wantErr bool
+	}{
+		{url: "slack+https://hooks.slack.com/services/x", want: &SlackNotifier{}},
+		{url: "teams+https://outlook.office.com/webhook/x", want: &TeamsNotifier{}},
+		{url: "exec:///bin/true", want: &ExecNotifier{}},
+		{url: "http://example.com/hook", want: &WebhookNotifier{}},
+		{url: "https://example.com/hook", want: &WebhookNotifier{}},
+		{url: "ftp://example.com/hook", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.url, func(t *testing.T) {
+			n, err := newFromURL(tc.url, nil)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("newFromURL(%q) = nil error, want one", tc.url)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("newFromURL(%q) returned error: %v", tc.url, err)
+			}
+
+			wantType := fmt.Sprintf("%T", tc.want)
+			gotType := fmt.Sprintf("%T", n)
+			if gotType != wantType {
+				t.Fatalf("newFromURL(%q) = %s, want %s", tc.url, gotType, wantType)
+			}
+		})
+	}
+}
+
+func TestNewFromURLsEmpty(t *testing.T) {
+	notifiers, err := NewFromURLs("", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(notifiers) != 0 {
+		t.Fatalf("NewFromURLs(\"\") = %d notifiers, want 0", len(notifiers))
+	}
+}
+
+func TestNewFromURLsMultiple(t *testing.T) {
+	notifiers, err := NewFromURLs("exec:///bin/true, exec:///bin/false", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(notifiers) != 2 {
+		t.Fatalf("NewFromURLs(...) = %d notifiers, want 2", len(notifiers))
+	}
+}
+
+type fakeNotifier struct {
+	err error
+}
+
+func (f fakeNotifier) Notify(ctx context.Context, event Event) error {
+	return f.err
+}
+
+func TestNotifyAllReturnsFirstError(t *testing.T) {
+	errA := errors.New("sink a failed")
+	errB := errors.New("sink b failed")
+
+	err := NotifyAll(context.Background(), []Notifier{
+		fakeNotifier{},
+		fakeNotifier{err: errA},
+		fakeNotifier{err: errB},
+	}, Event{Type: MigrateFailure})
+
+	if !errors.Is(err, errA) {
+		t.Fatalf("NotifyAll() = %v, want %v", err, errA)
+	}
+}
+
+func TestWebhookNotifierBuiltinPayload(t *testing.T) {
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	n, err := NewWebhookNotifier(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := n.Notify(context.Background(), Event{Type: MigrateSuccess, Command: "migrate"}); err != nil {
+		t.Fatalf("Notify() returned error: %v", err)
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(gotBody, &payload); err != nil {
+		t.Fatalf("invalid JSON payload: %v", err)
+	}
+
+	if payload.Event != MigrateSuccess || payload.Command != "migrate" {
+		t.Fatalf("Notify() posted %+v, want Event=%s Command=migrate", payload, MigrateSuccess)
+	}
+}
+
+func TestRenderPayload(t *testing.T) {
+	tmpl, err := template.New("t").Parse(`{"event":"{{.Event}}","command":"{{.Command}}"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body, err := RenderPayload(tmpl, Event{Type: MigrateSuccess, Command: "migrate"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `{"event":"migrate_success","command":"migrate"}`
+	if string(body) != want {
+		t.Fatalf("RenderPayload() = %s, want %s", body, want)
+	}
+}
@@ -0,0 +1,112 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+)
+
+// SlackField is a single key/value row attached to a Slack message.
+type SlackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// SlackAttachment is one attachment of a Slack incoming webhook message.
+type SlackAttachment struct {
+	Color    string       `json:"color"`
+	Pretext  string       `json:"pretext"`
+	Fallback string       `json:"fallback"`
+	Text     string       `json:"text"`
+	Fields   []SlackField `json:"fields"`
+}
+
+// SlackMessage is the payload posted to a Slack incoming webhook.
+type SlackMessage struct {
+	Attachments []SlackAttachment `json:"attachments"`
+}
+
+// SlackNotifier posts Events to a Slack incoming webhook URL.
+type SlackNotifier struct {
+	URL    string
+	Client *http.Client
+
+	// Template, if set, overrides the built-in SlackMessage payload: its
+	// rendered output is posted verbatim instead.
+	Template *template.Template
+}
+
+// NewSlackNotifier returns a Notifier that posts to the given Slack
+// incoming webhook URL.
+func NewSlackNotifier(url string) (*SlackNotifier, error) {
+	if url == "" {
+		return nil, fmt.Errorf("slack webhook url is required")
+	}
+
+	return &SlackNotifier{URL: url, Client: http.DefaultClient}, nil
+}
+
+// Notify implements Notifier.
+func (s *SlackNotifier) Notify(ctx context.Context, event Event) error {
+	var body []byte
+
+	if s.Template != nil {
+		rendered, err := RenderPayload(s.Template, event)
+		if err != nil {
+			return err
+		}
+		body = rendered
+	} else {
+		// The built-in payload is failure-only: it's meant for paging a
+		// channel, and successes/starts would just be noise. A custom
+		// --notify-payload-template isn't bound by that and can route any
+		// event.
+		if event.Type != MigrateFailure && event.Type != RollbackFailure {
+			return nil
+		}
+
+		fields := make([]SlackField, 0, len(event.Env))
+		for name, value := range event.Env {
+			fields = append(fields, SlackField{Title: name, Value: value})
+		}
+
+		msg := SlackMessage{
+			Attachments: []SlackAttachment{
+				{
+					Fallback: fmt.Sprintf("%s had error: %s", event.Command, event.Err),
+					Color:    "#FF0000",
+					Pretext:  fmt.Sprintf("There was an issue running %s on this instance.", event.Command),
+					Text:     event.Err.Error(),
+					Fields:   fields,
+				},
+			},
+		}
+
+		marshaled, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		body = marshaled
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("slack webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+)
+
+// TeamsFact is a single key/value row in a Teams MessageCard section.
+type TeamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// TeamsSection is one section of a Teams MessageCard.
+type TeamsSection struct {
+	ActivityTitle string      `json:"activityTitle"`
+	Text          string      `json:"text,omitempty"`
+	Facts         []TeamsFact `json:"facts,omitempty"`
+}
+
+// TeamsMessage is the MessageCard payload posted to a Microsoft Teams
+// incoming webhook connector.
+type TeamsMessage struct {
+	Type       string         `json:"@type"`
+	Context    string         `json:"@context"`
+	ThemeColor string         `json:"themeColor"`
+	Summary    string         `json:"summary"`
+	Sections   []TeamsSection `json:"sections"`
+}
+
+// TeamsNotifier posts Events to a Microsoft Teams incoming webhook connector.
+type TeamsNotifier struct {
+	URL    string
+	Client *http.Client
+
+	// Template, if set, overrides the built-in MessageCard payload: its
+	// rendered output is posted verbatim instead.
+	Template *template.Template
+}
+
+// NewTeamsNotifier returns a Notifier that posts to the given Teams
+// incoming webhook URL.
+func NewTeamsNotifier(url string) (*TeamsNotifier, error) {
+	if url == "" {
+		return nil, fmt.Errorf("teams webhook url is required")
+	}
+
+	return &TeamsNotifier{URL: url, Client: http.DefaultClient}, nil
+}
+
+// Notify implements Notifier.
+func (t *TeamsNotifier) Notify(ctx context.Context, event Event) error {
+	var body []byte
+
+	if t.Template != nil {
+		rendered, err := RenderPayload(t.Template, event)
+		if err != nil {
+			return err
+		}
+		body = rendered
+	} else {
+		// The built-in payload is failure-only: it's meant for paging a
+		// channel, and successes/starts would just be noise. A custom
+		// --notify-payload-template isn't bound by that and can route any
+		// event.
+		if event.Type != MigrateFailure && event.Type != RollbackFailure {
+			return nil
+		}
+
+		facts := make([]TeamsFact, 0, len(event.Env))
+		for name, value := range event.Env {
+			facts = append(facts, TeamsFact{Name: name, Value: value})
+		}
+
+		msg := TeamsMessage{
+			Type:       "MessageCard",
+			Context:    "http://schema.org/extensions",
+			ThemeColor: "FF0000",
+			Summary:    fmt.Sprintf("%s had error", event.Command),
+			Sections: []TeamsSection{
+				{
+					ActivityTitle: fmt.Sprintf("There was an issue running %s on this instance.", event.Command),
+					Text:          event.Err.Error(),
+					Facts:         facts,
+				},
+			},
+		}
+
+		marshaled, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		body = marshaled
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("teams webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
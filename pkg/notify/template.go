@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"bytes"
+	"os"
+	"text/template"
+	"time"
+)
+
+// TemplateData is the set of fields available to a custom
+// --notify-payload-template, mirroring the SLACK_CUSTOM_PAYLOAD convention.
+type TemplateData struct {
+	Event     EventType
+	Command   string
+	Migration string
+	Duration  time.Duration
+	Error     string
+	Env       map[string]string
+	Hostname  string
+}
+
+// newTemplateData builds the TemplateData for event.
+func newTemplateData(event Event) TemplateData {
+	errMsg := ""
+	if event.Err != nil {
+		errMsg = event.Err.Error()
+	}
+
+	hostname, _ := os.Hostname()
+
+	return TemplateData{
+		Event:     event.Type,
+		Command:   event.Command,
+		Migration: event.Filename,
+		Duration:  event.Duration,
+		Error:     errMsg,
+		Env:       event.Env,
+		Hostname:  hostname,
+	}
+}
+
+// RenderPayload renders tmpl against event and returns the resulting bytes,
+// which are posted verbatim as the webhook body in place of any sink's
+// built-in payload struct.
+func RenderPayload(tmpl *template.Template, event Event) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, newTemplateData(event)); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
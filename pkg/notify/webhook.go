@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+)
+
+// WebhookPayload is the default JSON body posted by WebhookNotifier when no
+// custom template has been configured.
+type WebhookPayload struct {
+	Event    EventType         `json:"event"`
+	Command  string            `json:"command"`
+	Filename string            `json:"filename,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Env      map[string]string `json:"env,omitempty"`
+}
+
+// WebhookNotifier posts Events as JSON to a generic HTTP(S) endpoint.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+
+	// Template, if set, overrides the built-in WebhookPayload: its rendered
+	// output is posted verbatim instead.
+	Template *template.Template
+}
+
+// NewWebhookNotifier returns a Notifier that posts a JSON payload to url for
+// every event.
+func NewWebhookNotifier(url string) (*WebhookNotifier, error) {
+	if url == "" {
+		return nil, fmt.Errorf("webhook url is required")
+	}
+
+	return &WebhookNotifier{URL: url, Client: http.DefaultClient}, nil
+}
+
+// Notify implements Notifier.
+func (w *WebhookNotifier) Notify(ctx context.Context, event Event) error {
+	var body []byte
+
+	if w.Template != nil {
+		rendered, err := RenderPayload(w.Template, event)
+		if err != nil {
+			return err
+		}
+		body = rendered
+	} else {
+		payload := WebhookPayload{
+			Event:    event.Type,
+			Command:  event.Command,
+			Filename: event.Filename,
+			Env:      event.Env,
+		}
+		if event.Err != nil {
+			payload.Error = event.Err.Error()
+		}
+
+		marshaled, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = marshaled
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}